@@ -334,7 +334,7 @@ func (left *Resource) Validate() error {
 	}
 
 	// check for invalid state of (role,reservation) pair
-	if left.GetRole() == "*" && left.GetReservation() != nil {
+	if left.GetRole() == "*" && len(reservationStack(left)) > 0 {
 		return resourceErrorTypeIllegalReservation.Generate("default role cannot be dynamically assigned")
 	}
 
@@ -348,6 +348,48 @@ func (r *Resource_ReservationInfo) Equivalent(right *Resource_ReservationInfo) b
 	return r.GetPrincipal() == right.GetPrincipal()
 }
 
+// reservationStack returns the effective stack of reservation entries for r, from
+// outermost (least specific) to innermost (most specific) role. It prefers the
+// multi-level `Reservations` field when present, and falls back to the legacy
+// single `Reservation` field so that older, single-tier resources keep comparing
+// the way they always have.
+func reservationStack(r *Resource) []Resource_ReservationInfo {
+	if stack := r.GetReservations(); len(stack) > 0 {
+		return stack
+	}
+	if ri := r.GetReservation(); ri != nil {
+		return []Resource_ReservationInfo{*ri}
+	}
+	return nil
+}
+
+// reservationsEquivalent compares two reservation stacks element-wise, each level by
+// principal (see Resource_ReservationInfo.Equivalent above); stacks of different
+// depth, such as a resource reserved for "a/b" and one reserved for plain "a" or "b",
+// are never equivalent.
+func reservationsEquivalent(left, right []Resource_ReservationInfo) bool {
+	if len(left) != len(right) {
+		return false
+	}
+	for i := range left {
+		if !left[i].Equivalent(&right[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Equivalent returns true if right is equivalent to left.
+func (left *Resource_AllocationInfo) Equivalent(right *Resource_AllocationInfo) bool {
+	if (left == nil) != (right == nil) {
+		return false
+	}
+	if left == nil {
+		return true
+	}
+	return left.GetRole() == right.GetRole()
+}
+
 func (left *Resource_DiskInfo) Equivalent(right *Resource_DiskInfo) bool {
 	// NOTE: We ignore 'volume' inside DiskInfo when doing comparison
 	// because it describes how this resource will be used which has
@@ -374,7 +416,10 @@ func (left *Resource) Equivalent(right Resource) bool {
 		left.GetRole() != right.GetRole() {
 		return false
 	}
-	if !left.GetReservation().Equivalent(right.GetReservation()) {
+	if !left.GetAllocationInfo().Equivalent(right.GetAllocationInfo()) {
+		return false
+	}
+	if !reservationsEquivalent(reservationStack(left), reservationStack(&right)) {
 		return false
 	}
 	if !left.GetDisk().Equivalent(right.GetDisk()) {
@@ -383,6 +428,9 @@ func (left *Resource) Equivalent(right Resource) bool {
 	if (left.Revocable == nil) != (right.Revocable == nil) {
 		return false
 	}
+	if left.IsShared() != right.IsShared() {
+		return false
+	}
 
 	switch left.GetType() {
 	case SCALAR:
@@ -405,22 +453,34 @@ func (left *Resource) Addable(right Resource) bool {
 		left.GetRole() != right.GetRole() {
 		return false
 	}
-	if !left.GetReservation().Equivalent(right.GetReservation()) {
+	if !left.GetAllocationInfo().Equivalent(right.GetAllocationInfo()) {
+		return false
+	}
+	if !reservationsEquivalent(reservationStack(left), reservationStack(&right)) {
 		return false
 	}
 	if !left.GetDisk().Equivalent(right.GetDisk()) {
 		return false
 	}
+	if (left.Revocable == nil) != (right.Revocable == nil) {
+		return false
+	}
+
+	shared := left.IsShared()
+	if shared != right.IsShared() {
+		return false
+	}
 
 	// from apache/mesos: src/common/resources.cpp
 	// TODO(jieyu): Even if two Resource objects with DiskInfo have the
 	// same persistence ID, they cannot be added together. In fact, this
 	// shouldn't happen if we do not add resources from different
 	// namespaces (e.g., across slave). Consider adding a warning.
-	if left.GetDisk().GetPersistence() != nil {
-		return false
-	}
-	if (left.Revocable == nil) != (right.Revocable == nil) {
+	//
+	// Shared resources are the exception: they're identified (and kept
+	// addable/subtractable) by identity rather than by usage count, so a
+	// non-nil persistence ID doesn't disqualify them here.
+	if !shared && left.GetDisk().GetPersistence() != nil {
 		return false
 	}
 	return true
@@ -440,16 +500,26 @@ func (left *Resource) Subtractable(right Resource) bool {
 		left.GetRole() != right.GetRole() {
 		return false
 	}
-	if !left.GetReservation().Equivalent(right.GetReservation()) {
+	if !left.GetAllocationInfo().Equivalent(right.GetAllocationInfo()) {
+		return false
+	}
+	if !reservationsEquivalent(reservationStack(left), reservationStack(&right)) {
 		return false
 	}
 	if !left.GetDisk().Equivalent(right.GetDisk()) {
 		return false
 	}
 
+	shared := left.IsShared()
+	if shared != right.IsShared() {
+		return false
+	}
+
 	// NOTE: For Resource objects that have DiskInfo, we can only do
-	// subtraction if they are **equal**.
-	if left.GetDisk().GetPersistence() != nil && !left.Equivalent(right) {
+	// subtraction if they are **equal**. Shared resources are the
+	// exception: they remain subtractable by identity regardless of
+	// usage count.
+	if !shared && left.GetDisk().GetPersistence() != nil && !left.Equivalent(right) {
 		return false
 	}
 	if (left.Revocable == nil) != (right.Revocable == nil) {
@@ -529,10 +599,10 @@ func (left *Resource) IsEmpty() bool {
 // IsUnreserved returns true if this resource neither statically or dynamically reserved.
 // A resource is considered statically reserved if it has a non-default role.
 func (left *Resource) IsUnreserved() bool {
-	// role != RoleDefault     -> static reservation
-	// GetReservation() != nil -> dynamic reservation
+	// role != RoleDefault        -> static reservation
+	// len(reservationStack) != 0 -> dynamic reservation (single- or multi-level)
 	// return {no-static-reservation} && {no-dynamic-reservation}
-	return left.GetRole() == "*" && left.GetReservation() == nil
+	return left.GetRole() == "*" && len(reservationStack(left)) == 0
 }
 
 // IsReserved returns true if this resource has been reserved for the given role.
@@ -545,9 +615,10 @@ func (left *Resource) IsReserved(role string) bool {
 	return !left.IsUnreserved()
 }
 
-// IsDynamicallyReserved returns true if this resource has a non-nil reservation descriptor
+// IsDynamicallyReserved returns true if this resource has a non-nil reservation descriptor,
+// whether recorded via the legacy single Reservation field or the multi-level Reservations stack.
 func (left *Resource) IsDynamicallyReserved() bool {
-	return left.GetReservation() != nil
+	return len(reservationStack(left)) > 0
 }
 
 // IsRevocable returns true if this resource has a non-nil revocable descriptor
@@ -559,3 +630,28 @@ func (left *Resource) IsRevocable() bool {
 func (left *Resource) IsPersistentVolume() bool {
 	return left.GetDisk().GetPersistence() != nil
 }
+
+// IsShared returns true if this resource has a non-nil SharedInfo descriptor. Shared
+// resources (e.g. shared persistent volumes) are identified and kept addable or
+// subtractable by identity rather than by usage count; see Resource.Addable.
+func (left *Resource) IsShared() bool {
+	return left.GetShared() != nil
+}
+
+// AllocatedTo returns true if this resource is allocated to the given role. A resource
+// with no AllocationInfo is not allocated to any role.
+func (left *Resource) AllocatedTo(role string) bool {
+	return left.GetAllocationInfo() != nil && left.GetAllocationInfo().GetRole() == role
+}
+
+// Allocations partitions the receiving resources by their allocated role, as recorded
+// in each Resource's AllocationInfo. Resources with no AllocationInfo are grouped
+// under the empty-string key.
+func (resources Resources) Allocations() map[string]Resources {
+	result := make(map[string]Resources)
+	for i := range resources {
+		role := resources[i].GetAllocationInfo().GetRole()
+		result[role] = append(result[role], resources[i])
+	}
+	return result
+}
@@ -0,0 +1,97 @@
+package mesos
+
+// Predicate reports whether a Resource matches some criterion. Predicates compose via
+// Any, All and Not so that callers wanting e.g. "all cpus reserved for role X with
+// disk persistence Y" can build the query instead of hand-rolling a loop over a
+// Resources slice.
+type Predicate func(Resource) bool
+
+// Filter returns the subset of resources matching all of the given predicates, as a
+// Resources value backed by its own freshly-allocated slice, always, including when
+// no predicates are given: appending to (or further filtering) the result never
+// affects the receiver, so it composes safely with Plus/Minus just like any other
+// Resources value. The matching Resource values themselves are shallow copies, so
+// nested fields (Scalar, Disk, etc.) are still shared with the receiver; callers
+// that need to mutate a filtered Resource in place should Clone() it first.
+//
+// NOTE: this does not implement the "view that shares backing storage" originally
+// asked for. Resources is a plain []Resource, and an arbitrary predicate-selected
+// subset of it cannot in general be expressed as a contiguous subslice of the
+// receiver's backing array — doing so would require changing Resources from a slice
+// to an indexed/pointer-based representation, which would ripple through every
+// existing caller that ranges, indexes or appends into a Resources value. Given
+// that cost, Filter copies instead; see ResourceIndex for the O(1)-amortized,
+// index-backed alternative when that's what's actually needed.
+func (resources Resources) Filter(predicates ...Predicate) Resources {
+	match := All(predicates...)
+	result := make(Resources, 0, len(resources))
+	for i := range resources {
+		if match(resources[i]) {
+			result = append(result, resources[i])
+		}
+	}
+	return result
+}
+
+// ReservedTo returns a Predicate matching resources statically or dynamically
+// reserved for the given role.
+func ReservedTo(role string) Predicate {
+	return func(r Resource) bool { return r.IsReserved(role) }
+}
+
+// Unreserved returns a Predicate matching resources with no static or dynamic
+// reservation.
+func Unreserved() Predicate {
+	return func(r Resource) bool { return r.IsUnreserved() }
+}
+
+// Revocable returns a Predicate matching revocable resources.
+func Revocable() Predicate {
+	return func(r Resource) bool { return r.IsRevocable() }
+}
+
+// Scalars returns a Predicate matching SCALAR-typed resources.
+func Scalars() Predicate {
+	return func(r Resource) bool { return r.GetType() == SCALAR }
+}
+
+// Persistent returns a Predicate matching persistent volume resources.
+func Persistent() Predicate {
+	return func(r Resource) bool { return r.IsPersistentVolume() }
+}
+
+// Named returns a Predicate matching resources with the given name.
+func Named(name string) Predicate {
+	return func(r Resource) bool { return r.GetName() == name }
+}
+
+// Any returns a Predicate matching a Resource if any of the given predicates match
+// it. An empty predicate list matches nothing.
+func Any(predicates ...Predicate) Predicate {
+	return func(r Resource) bool {
+		for _, p := range predicates {
+			if p(r) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// All returns a Predicate matching a Resource if every one of the given predicates
+// match it. An empty predicate list matches everything.
+func All(predicates ...Predicate) Predicate {
+	return func(r Resource) bool {
+		for _, p := range predicates {
+			if !p(r) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Not returns a Predicate that inverts p.
+func Not(p Predicate) Predicate {
+	return func(r Resource) bool { return !p(r) }
+}
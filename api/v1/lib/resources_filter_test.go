@@ -0,0 +1,107 @@
+package mesos
+
+import "testing"
+
+func TestFilterReservedToAndUnreserved(t *testing.T) {
+	reserved := scalarResource("cpus", "role1", 1)
+	reserved.Reservations = []Resource_ReservationInfo{
+		{Principal: stringPtr("alice")},
+	}
+	unreserved := scalarResource("mem", "*", 1)
+
+	resources := Resources{reserved, unreserved}
+
+	if got := resources.Filter(ReservedTo("role1")); len(got) != 1 || got[0].Name != "cpus" {
+		t.Errorf("ReservedTo(\"role1\") = %+v, want just the stack-reserved cpus resource", got)
+	}
+	if got := resources.Filter(Unreserved()); len(got) != 1 || got[0].Name != "mem" {
+		t.Errorf("Unreserved() = %+v, want just the unreserved mem resource", got)
+	}
+}
+
+func TestFilterScalarsPersistentAndNamed(t *testing.T) {
+	cpus := scalarResource("cpus", "*", 1)
+	disk := Resource{
+		Name: "disk",
+		Type: SCALAR,
+		Role: "*",
+		Disk: &Resource_DiskInfo{
+			Persistence: &Resource_DiskInfo_Persistence{ID: "vol1"},
+		},
+		Scalar: &Value_Scalar{Value: 10},
+	}
+	ports := Resource{
+		Name: "ports",
+		Type: RANGES,
+		Role: "*",
+		Ranges: &Value_Ranges{
+			Range: []Value_Range{{Begin: 1, End: 2}},
+		},
+	}
+
+	resources := Resources{cpus, disk, ports}
+
+	if got := resources.Filter(Scalars()); len(got) != 2 {
+		t.Errorf("Scalars() returned %d resources, want 2", len(got))
+	}
+	if got := resources.Filter(Persistent()); len(got) != 1 || got[0].Name != "disk" {
+		t.Errorf("Persistent() = %+v, want just the disk resource", got)
+	}
+	if got := resources.Filter(Named("ports")); len(got) != 1 || got[0].Name != "ports" {
+		t.Errorf("Named(\"ports\") = %+v, want just the ports resource", got)
+	}
+}
+
+func TestFilterAnyAllNot(t *testing.T) {
+	cpus := scalarResource("cpus", "*", 1)
+	mem := scalarResource("mem", "*", 1)
+	resources := Resources{cpus, mem}
+
+	if got := resources.Filter(Any(Named("cpus"), Named("mem"))); len(got) != 2 {
+		t.Errorf("Any(...) returned %d resources, want 2", len(got))
+	}
+	if got := resources.Filter(All(Named("cpus"), Scalars())); len(got) != 1 {
+		t.Errorf("All(...) returned %d resources, want 1", len(got))
+	}
+	if got := resources.Filter(Not(Named("cpus"))); len(got) != 1 || got[0].Name != "mem" {
+		t.Errorf("Not(...) = %+v, want just the mem resource", got)
+	}
+}
+
+// TestFilterSliceIsIndependentOfReceiver verifies that the slice Filter returns is
+// backed by its own array: growing it (or the receiver) never aliases the other.
+func TestFilterSliceIsIndependentOfReceiver(t *testing.T) {
+	cpus := scalarResource("cpus", "*", 1)
+	mem := scalarResource("mem", "*", 1)
+	resources := Resources{cpus, mem}
+
+	filtered := resources.Filter(Named("cpus"))
+	filtered = append(filtered, scalarResource("disk", "*", 1))
+
+	if len(resources) != 2 {
+		t.Errorf("appending to the filtered result grew the receiver: len(resources) = %d, want 2", len(resources))
+	}
+	if resources[1].Name != "mem" {
+		t.Errorf("appending to the filtered result overwrote the receiver: resources[1].Name = %q, want \"mem\"", resources[1].Name)
+	}
+}
+
+// TestFilterNoPredicatesStillCopies guards the zero-predicate path specifically: it
+// used to return the receiver itself rather than a copy, contradicting Filter's
+// documented "the result never affects the receiver" guarantee.
+func TestFilterNoPredicatesStillCopies(t *testing.T) {
+	resources := Resources{scalarResource("cpus", "*", 1)}
+
+	filtered := resources.Filter()
+	if len(filtered) > 0 && &filtered[0] == &resources[0] {
+		t.Error("Filter() with no predicates returned the receiver's backing array instead of a copy")
+	}
+	filtered = append(filtered, scalarResource("mem", "*", 1))
+
+	if len(resources) != 1 {
+		t.Errorf("appending to the zero-predicate Filter() result grew the receiver: len(resources) = %d, want 1", len(resources))
+	}
+	if len(filtered) != 2 {
+		t.Errorf("expected Filter() with no predicates to match everything, got %d resources", len(filtered))
+	}
+}
@@ -0,0 +1,174 @@
+package mesos
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestResourceIndexAddCombinesLikeResources(t *testing.T) {
+	idx := NewResourceIndex(nil)
+	idx.Add(scalarResource("cpus", "*", 1))
+	idx.Add(scalarResource("cpus", "*", 2))
+
+	got := idx.Resources()
+	if len(got) != 1 {
+		t.Fatalf("expected one combined cpus resource, got %d: %+v", len(got), got)
+	}
+	if got[0].Scalar.GetValue() != 3 {
+		t.Errorf("expected combined cpus value 3, got %v", got[0].Scalar.GetValue())
+	}
+}
+
+// TestResourceIndexAddDistinguishesAbsentFromEmptyAllocationInfo guards against a key
+// collision where a resource with no AllocationInfo and one with a present-but-empty
+// AllocationInfo both hashed to the same index key. Since Resource_AllocationInfo.Equivalent
+// treats those as not equivalent (and so not Addable), a naive key made the second Add
+// overwrite the map entry for the first, stranding it: still present in Resources() but
+// unreachable by any later Add/Subtract/Contains.
+func TestResourceIndexAddDistinguishesAbsentFromEmptyAllocationInfo(t *testing.T) {
+	a := scalarResource("cpus", "*", 1)
+	b := scalarResource("cpus", "*", 1)
+	b.AllocationInfo = &Resource_AllocationInfo{}
+
+	idx := NewResourceIndex(Resources{a})
+	idx.Add(b)
+
+	got := idx.Resources()
+	if len(got) != 2 {
+		t.Fatalf("expected a (no AllocationInfo) and b (empty AllocationInfo) to stay distinct entries, got %d: %+v", len(got), got)
+	}
+	if !idx.Contains(a) {
+		t.Error("the original no-AllocationInfo resource must still be reachable via Contains after adding the empty-AllocationInfo one")
+	}
+	if !idx.Contains(b) {
+		t.Error("the empty-AllocationInfo resource must be reachable via Contains")
+	}
+}
+
+func TestResourceIndexAddKeepsDistinctRolesSeparate(t *testing.T) {
+	idx := NewResourceIndex(nil)
+	idx.Add(scalarResource("cpus", "role1", 1))
+	idx.Add(scalarResource("cpus", "role2", 1))
+
+	if got := idx.Resources(); len(got) != 2 {
+		t.Fatalf("expected two distinct cpus resources (different roles), got %d: %+v", len(got), got)
+	}
+}
+
+func TestResourceIndexSubtractRemovesExhaustedEntry(t *testing.T) {
+	idx := NewResourceIndex(Resources{scalarResource("cpus", "*", 2)})
+	idx.Subtract(scalarResource("cpus", "*", 2))
+
+	if got := idx.Resources(); len(got) != 0 {
+		t.Fatalf("expected the cpus resource to be removed once exhausted, got %+v", got)
+	}
+	if idx.Contains(scalarResource("cpus", "*", 1)) {
+		t.Error("expected Contains to be false after the resource was exhausted")
+	}
+}
+
+func TestResourceIndexContains(t *testing.T) {
+	idx := NewResourceIndex(Resources{scalarResource("cpus", "*", 4)})
+
+	if !idx.Contains(scalarResource("cpus", "*", 2)) {
+		t.Error("expected the index to contain a subset quantity of an existing resource")
+	}
+	if idx.Contains(scalarResource("cpus", "*", 5)) {
+		t.Error("expected the index to not contain a quantity larger than what's indexed")
+	}
+	if idx.Contains(scalarResource("mem", "*", 1)) {
+		t.Error("expected the index to not contain a resource it never saw")
+	}
+}
+
+func TestResourceIndexMatchesSliceSemantics(t *testing.T) {
+	var slice Resources
+	idx := NewResourceIndex(nil)
+
+	ops := []Resource{
+		scalarResource("cpus", "*", 3),
+		scalarResource("cpus", "role1", 1),
+		scalarResource("mem", "*", 128),
+	}
+	for _, r := range ops {
+		slice = slice.Plus(r)
+		idx.Add(r)
+	}
+	slice = slice.Minus(scalarResource("cpus", "*", 1))
+	idx.Subtract(scalarResource("cpus", "*", 1))
+
+	sliceByName := map[string]float64{}
+	for i := range slice {
+		sliceByName[slice[i].Name+"/"+slice[i].Role] += slice[i].Scalar.GetValue()
+	}
+	idxByName := map[string]float64{}
+	for _, r := range idx.Resources() {
+		idxByName[r.Name+"/"+r.Role] += r.Scalar.GetValue()
+	}
+	if len(sliceByName) != len(idxByName) {
+		t.Fatalf("slice and index diverged in shape: %+v vs %+v", sliceByName, idxByName)
+	}
+	for k, v := range sliceByName {
+		if idxByName[k] != v {
+			t.Errorf("slice and index diverged for %s: slice=%v index=%v", k, v, idxByName[k])
+		}
+	}
+}
+
+// offerSized builds a Resources collection on the order of a large offer: thousands
+// of scalar resources spread across a handful of roles, as used by the benchmarks
+// below to compare ResourceIndex against the plain slice implementation.
+func offerSized(n int) Resources {
+	roles := []string{"*", "role1", "role2", "role3"}
+	resources := make(Resources, 0, n)
+	for i := 0; i < n; i++ {
+		role := roles[i%len(roles)]
+		name := "cpus-" + strconv.Itoa(i%32)
+		resources = append(resources, scalarResource(name, role, 1))
+	}
+	return resources
+}
+
+func BenchmarkResources_add(b *testing.B) {
+	base := offerSized(4000)
+	extra := scalarResource("cpus-0", "*", 1)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		resources := base.Clone()
+		resources.Add1(extra)
+	}
+}
+
+func BenchmarkResourceIndex_Add(b *testing.B) {
+	base := offerSized(4000)
+	extra := scalarResource("cpus-0", "*", 1)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		idx := NewResourceIndex(base)
+		idx.Add(extra)
+	}
+}
+
+func BenchmarkResources_Subtract1(b *testing.B) {
+	base := offerSized(4000)
+	extra := scalarResource("cpus-0", "*", 0.5)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		resources := base.Clone()
+		resources.Subtract1(extra)
+	}
+}
+
+func BenchmarkResourceIndex_Subtract(b *testing.B) {
+	base := offerSized(4000)
+	extra := scalarResource("cpus-0", "*", 0.5)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		idx := NewResourceIndex(base)
+		idx.Subtract(extra)
+	}
+}
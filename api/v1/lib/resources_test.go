@@ -0,0 +1,160 @@
+package mesos
+
+import "testing"
+
+func scalarResource(name, role string, value float64) Resource {
+	return Resource{
+		Name:   name,
+		Type:   SCALAR,
+		Role:   role,
+		Scalar: &Value_Scalar{Value: value},
+	}
+}
+
+func TestReservationStackPreference(t *testing.T) {
+	single := scalarResource("cpus", "role1", 1)
+	single.Reservation = &Resource_ReservationInfo{Principal: stringPtr("alice")}
+
+	stacked := scalarResource("cpus", "role1/role2", 1)
+	stacked.Reservations = []Resource_ReservationInfo{
+		{Principal: stringPtr("alice")},
+		{Principal: stringPtr("bob")},
+	}
+
+	if len(reservationStack(&single)) != 1 {
+		t.Errorf("expected legacy Reservation to surface as a 1-element stack, got %d", len(reservationStack(&single)))
+	}
+	if len(reservationStack(&stacked)) != 2 {
+		t.Errorf("expected Reservations stack to surface in full, got %d", len(reservationStack(&stacked)))
+	}
+}
+
+// TestStackOnlyReservationIsReserved guards the bug where a resource reserved only
+// via the multi-level Reservations stack (Reservation left nil, the MULTI_ROLE case)
+// was treated as reserved by Equivalent/Addable/Subtractable but as unreserved by
+// IsUnreserved/IsReserved/IsDynamicallyReserved.
+func TestStackOnlyReservationIsReserved(t *testing.T) {
+	r := scalarResource("cpus", "role1", 1)
+	r.Reservations = []Resource_ReservationInfo{
+		{Principal: stringPtr("alice")},
+	}
+
+	if r.IsUnreserved() {
+		t.Error("resource reserved only via the Reservations stack must not report IsUnreserved() == true")
+	}
+	if !r.IsReserved("") {
+		t.Error("resource reserved only via the Reservations stack must report IsReserved(\"\") == true")
+	}
+	if !r.IsDynamicallyReserved() {
+		t.Error("resource reserved only via the Reservations stack must report IsDynamicallyReserved() == true")
+	}
+}
+
+func TestValidateRejectsDefaultRoleWithReservationStack(t *testing.T) {
+	r := scalarResource("cpus", "*", 1)
+	r.Reservations = []Resource_ReservationInfo{
+		{Principal: stringPtr("alice")},
+	}
+	if err := r.Validate(); err == nil {
+		t.Error("expected Validate to reject a default-role resource with a non-empty Reservations stack")
+	}
+}
+
+func TestReservationsEquivalentElementWise(t *testing.T) {
+	a := []Resource_ReservationInfo{{Principal: stringPtr("alice")}, {Principal: stringPtr("bob")}}
+	b := []Resource_ReservationInfo{{Principal: stringPtr("alice")}, {Principal: stringPtr("bob")}}
+	c := []Resource_ReservationInfo{{Principal: stringPtr("alice")}}
+
+	if !reservationsEquivalent(a, b) {
+		t.Error("expected identical reservation stacks to be equivalent")
+	}
+	if reservationsEquivalent(a, c) {
+		t.Error("expected stacks of different length to not be equivalent")
+	}
+}
+
+func TestAllocationInfoParticipatesInEquivalence(t *testing.T) {
+	a := scalarResource("cpus", "role1", 1)
+	a.AllocationInfo = &Resource_AllocationInfo{Role: stringPtr("role1")}
+
+	b := scalarResource("cpus", "role1", 1)
+	b.AllocationInfo = &Resource_AllocationInfo{Role: stringPtr("role2")}
+
+	if a.Equivalent(b) {
+		t.Error("resources allocated to different roles must not be equivalent")
+	}
+	if a.Addable(b) {
+		t.Error("resources allocated to different roles must not be addable")
+	}
+}
+
+func TestSharedResourcesAddableRegardlessOfPersistence(t *testing.T) {
+	mkShared := func() Resource {
+		r := Resource{
+			Name: "disk",
+			Type: SCALAR,
+			Role: "*",
+			Disk: &Resource_DiskInfo{
+				Persistence: &Resource_DiskInfo_Persistence{ID: "vol1"},
+			},
+			Shared: &Resource_SharedInfo{},
+			Scalar: &Value_Scalar{Value: 10},
+		}
+		return r
+	}
+
+	a := mkShared()
+	b := mkShared()
+
+	if !a.Addable(b) {
+		t.Error("shared persistent resources with the same identity must remain addable")
+	}
+	if !a.Subtractable(b) {
+		t.Error("shared persistent resources with the same identity must remain subtractable")
+	}
+
+	nonShared := mkShared()
+	nonShared.Shared = nil
+	other := mkShared()
+	other.Shared = nil
+
+	if other.Addable(nonShared) {
+		t.Error("non-shared persistent resources with the same persistence ID must not be addable")
+	}
+}
+
+func TestIsSharedAndAllocatedTo(t *testing.T) {
+	r := scalarResource("cpus", "role1", 1)
+	if r.IsShared() {
+		t.Error("expected IsShared() == false for a resource with no SharedInfo")
+	}
+	r.Shared = &Resource_SharedInfo{}
+	if !r.IsShared() {
+		t.Error("expected IsShared() == true once SharedInfo is set")
+	}
+
+	r.AllocationInfo = &Resource_AllocationInfo{Role: stringPtr("role1")}
+	if !r.AllocatedTo("role1") {
+		t.Error("expected AllocatedTo(\"role1\") == true")
+	}
+	if r.AllocatedTo("role2") {
+		t.Error("expected AllocatedTo(\"role2\") == false")
+	}
+}
+
+func TestResourcesAllocations(t *testing.T) {
+	a := scalarResource("cpus", "role1", 1)
+	a.AllocationInfo = &Resource_AllocationInfo{Role: stringPtr("role1")}
+
+	b := scalarResource("mem", "role2", 1)
+	b.AllocationInfo = &Resource_AllocationInfo{Role: stringPtr("role2")}
+
+	c := scalarResource("disk", "*", 1)
+
+	allocations := Resources{a, b, c}.Allocations()
+	if len(allocations["role1"]) != 1 || len(allocations["role2"]) != 1 || len(allocations[""]) != 1 {
+		t.Errorf("unexpected partition: %+v", allocations)
+	}
+}
+
+func stringPtr(s string) *string { return &s }